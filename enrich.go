@@ -0,0 +1,317 @@
+// +build mage
+
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jszwec/csvutil"
+)
+
+const (
+	httpCacheDir      = ".cache/http"
+	enrichUserAgent   = "readingListBot/1.0 (+https://github.com/codemicro/readingList)"
+	enrichMaxBodySize = 5 << 20 // 5MiB
+	enrichWorkerCount = 8
+)
+
+// enrichCacheTTL returns how long a cached fetch stays valid, overridable
+// via ENRICH_CACHE_TTL (e.g. "24h") for local testing.
+func enrichCacheTTL() time.Duration {
+	if raw := os.Getenv("ENRICH_CACHE_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return 7 * 24 * time.Hour
+}
+
+func cachePathFor(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return filepath.Join(httpCacheDir, hex.EncodeToString(sum[:])+".html.gz")
+}
+
+// readCache returns the cached body for url if present and not expired.
+func readCache(url string) ([]byte, bool) {
+	path := cachePathFor(url)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if time.Since(info.ModTime()) > enrichCacheTTL() {
+		return nil, false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, false
+	}
+	defer gz.Close()
+
+	body, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return nil, false
+	}
+
+	return body, true
+}
+
+func writeCache(url string, body []byte) error {
+	if err := os.MkdirAll(httpCacheDir, 0777); err != nil {
+		return err
+	}
+
+	path := cachePathFor(url)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+
+	_, err = gz.Write(body)
+	return err
+}
+
+// robotsAllows does a minimal robots.txt check: it only honours a
+// "User-agent: *" group's Disallow rules, which is enough to keep this
+// fetcher polite without pulling in a full parser.
+func robotsAllows(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", u.Scheme, u.Host)
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return true
+	}
+	req.Header.Set("User-Agent", enrichUserAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return true
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, enrichMaxBodySize))
+	if err != nil {
+		return true
+	}
+
+	applies := false
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+
+		switch key {
+		case "user-agent":
+			applies = value == "*"
+		case "disallow":
+			if applies && value != "" && strings.HasPrefix(u.Path, value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// fetchPage fetches rawURL, consulting and populating the on-disk cache.
+func fetchPage(rawURL string) ([]byte, error) {
+	if body, ok := readCache(rawURL); ok {
+		return body, nil
+	}
+
+	if !robotsAllows(rawURL) {
+		return nil, fmt.Errorf("robots.txt disallows fetching %s", rawURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", enrichUserAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", rawURL, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, enrichMaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > enrichMaxBodySize {
+		return nil, fmt.Errorf("fetching %s: response exceeds %d bytes", rawURL, enrichMaxBodySize)
+	}
+
+	if err := writeCache(rawURL, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+var (
+	metaTagPattern  = regexp.MustCompile(`(?is)<meta\s+[^>]*>`)
+	metaAttrPattern = regexp.MustCompile(`(?is)(name|property)\s*=\s*["']([^"']+)["']`)
+	metaContPattern = regexp.MustCompile(`(?is)content\s*=\s*["']([^"']*)["']`)
+	titleTagPattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+type pageMetadata struct {
+	Title       string
+	Description string
+	Image       string
+}
+
+// extractMetadata pulls OpenGraph/Twitter Card/<title>/meta-description
+// values out of an HTML document, preferring OpenGraph when present.
+func extractMetadata(html string) pageMetadata {
+	meta := map[string]string{}
+	for _, tag := range metaTagPattern.FindAllString(html, -1) {
+		nameMatch := metaAttrPattern.FindStringSubmatch(tag)
+		contMatch := metaContPattern.FindStringSubmatch(tag)
+		if nameMatch == nil || contMatch == nil {
+			continue
+		}
+		meta[strings.ToLower(nameMatch[2])] = contMatch[1]
+	}
+
+	pick := func(keys ...string) string {
+		for _, k := range keys {
+			if v := meta[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	var out pageMetadata
+	out.Title = pick("og:title", "twitter:title")
+	if out.Title == "" {
+		if m := titleTagPattern.FindStringSubmatch(html); m != nil {
+			out.Title = strings.TrimSpace(m[1])
+		}
+	}
+	out.Description = pick("og:description", "twitter:description", "description")
+	out.Image = pick("og:image", "twitter:image")
+
+	return out
+}
+
+// EnrichEntries fetches missing Title/Description/Image fields for every
+// entry in readingList.csv from its URL and rewrites the CSV in place.
+func EnrichEntries() error {
+	const readingListFile = "readingList.csv"
+
+	fcont, err := ioutil.ReadFile(readingListFile)
+	if err != nil {
+		return err
+	}
+
+	var entries []*readingListEntry
+	if err := csvutil.Unmarshal(fcont, &entries); err != nil {
+		return err
+	}
+
+	type job struct {
+		entry *readingListEntry
+	}
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var fetchErrs []error
+
+	for i := 0; i < enrichWorkerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				body, err := fetchPage(j.entry.URL)
+				if err != nil {
+					mu.Lock()
+					fetchErrs = append(fetchErrs, err)
+					mu.Unlock()
+					continue
+				}
+
+				meta := extractMetadata(string(body))
+
+				mu.Lock()
+				if j.entry.Title == "" {
+					j.entry.Title = meta.Title
+				}
+				if j.entry.Description == "" {
+					j.entry.Description = meta.Description
+				}
+				if j.entry.Image == "" {
+					j.entry.Image = meta.Image
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, entry := range entries {
+		if entry.Title == "" || entry.Description == "" || entry.Image == "" {
+			jobs <- job{entry: entry}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range fetchErrs {
+		fmt.Fprintln(os.Stderr, "enrich:", err)
+	}
+
+	out, err := csvutil.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(readingListFile, out, 0644)
+}
@@ -0,0 +1,167 @@
+// +build mage
+
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"time"
+)
+
+const feedHost = "codemicro.github.io"
+const feedSiteURL = "https://codemicro.github.io/readingList/"
+
+// feedTagEpoch anchors every tag: URI (RFC 4151 section 2.2). It must stay fixed
+// once chosen: deriving it from the corpus (e.g. the oldest entry's date)
+// would change every entry's GUID whenever backfilling or removing an
+// entry shifted that minimum.
+const feedTagEpoch = "2020-01-01"
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomThumbnail struct {
+	XMLName xml.Name `xml:"media:thumbnail"`
+	URL     string   `xml:"url,attr"`
+}
+
+type atomEntry struct {
+	Title     string         `xml:"title"`
+	Link      atomLink       `xml:"link"`
+	ID        string         `xml:"id"`
+	Updated   string         `xml:"updated"`
+	Summary   string         `xml:"summary"`
+	Thumbnail *atomThumbnail `xml:"media:thumbnail,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName    xml.Name    `xml:"feed"`
+	Xmlns      string      `xml:"xmlns,attr"`
+	XmlnsMedia string      `xml:"xmlns:media,attr"`
+	Title      string      `xml:"title"`
+	ID         string      `xml:"id"`
+	Updated    string      `xml:"updated"`
+	Link       atomLink    `xml:"link"`
+	Entries    []atomEntry `xml:"entry"`
+}
+
+// entryTagURI builds a stable tag: URI (RFC 4151) for an entry so that
+// re-sorting or re-fetching the CSV doesn't change an entry's identity.
+func entryTagURI(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return fmt.Sprintf("tag:%s,%s:%s", feedHost, feedTagEpoch, hex.EncodeToString(sum[:]))
+}
+
+// buildAtomFeed renders entries as an Atom 1.0 feed.
+func buildAtomFeed(entries entrySlice, siteTitle string) ([]byte, error) {
+	feed := atomFeed{
+		Xmlns:      "http://www.w3.org/2005/Atom",
+		XmlnsMedia: "http://search.yahoo.com/mrss/",
+		Title:      siteTitle,
+		ID:         fmt.Sprintf("tag:%s,%s:root", feedHost, feedTagEpoch),
+		Updated:    time.Now().UTC().Format(time.RFC3339),
+		Link:       atomLink{Href: feedSiteURL},
+	}
+
+	for _, article := range entries {
+		entry := atomEntry{
+			Title:   article.Title,
+			Link:    atomLink{Href: article.URL},
+			ID:      entryTagURI(article.URL),
+			Updated: article.Date.Format(time.RFC3339),
+			Summary: article.Description,
+		}
+		if article.Image != "" {
+			entry.Thumbnail = &atomThumbnail{URL: article.Image}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
+
+type rssImage struct {
+	URL string `xml:"url"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	GUID        string    `xml:"guid"`
+	PubDate     string    `xml:"pubDate"`
+	Description string    `xml:"description"`
+	Enclosure   *rssEnclo `xml:"enclosure,omitempty"`
+}
+
+type rssEnclo struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// imageMIMEType guesses an image's MIME type from its URL's file extension,
+// returning "" if it can't be determined.
+func imageMIMEType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return mime.TypeByExtension(path.Ext(u.Path))
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// buildRSSFeed renders entries as an RSS 2.0 feed for clients that don't
+// speak Atom.
+func buildRSSFeed(entries entrySlice, siteTitle string) ([]byte, error) {
+	channel := rssChannel{
+		Title:       siteTitle,
+		Link:        feedSiteURL,
+		Description: siteTitle,
+	}
+
+	for _, article := range entries {
+		item := rssItem{
+			Title:       article.Title,
+			Link:        article.URL,
+			GUID:        entryTagURI(article.URL),
+			PubDate:     article.Date.Format(time.RFC1123Z),
+			Description: article.Description,
+		}
+		if article.Image != "" {
+			item.Enclosure = &rssEnclo{URL: article.Image, Type: imageMIMEType(article.Image)}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	feed := rssFeed{Version: "2.0", Channel: channel}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"sort"
+	"strings"
 	"text/template"
 	"time"
 
@@ -18,12 +19,15 @@ import (
 
 const dateFormat = "2006-01-02"
 
+const pageTitle = "akp's reading list"
+
 type readingListEntry struct {
 	URL         string    `csv:"url,omitempty"`
 	Title       string    `csv:"title,omitempty"`
 	Description string    `csv:"description,omitempty"`
 	Image       string    `csv:"image,omitempty"`
 	Date        time.Time `csv:"date,omitempty"`
+	Tags        tagList   `csv:"tags,omitempty"`
 }
 
 // renderAnchor renders a HTML anchor tag
@@ -127,6 +131,36 @@ func groupEntriesByMonth(entries []*readingListEntry) entryGroupSlice {
 	return o
 }
 
+// renderEntryHTML renders a single article as a <li> fragment.
+func renderEntryHTML(article *readingListEntry) string {
+	titleLine := daz.H("summary", renderAnchor(article.Title, article.URL, false), " - "+article.Date.Format(dateFormat))
+
+	detailedInfo := []interface{}{}
+
+	{
+		var descriptionContent string
+		if article.Description != "" {
+			descriptionContent = article.Description
+		} else {
+			descriptionContent = "<none>"
+		}
+		detailedInfo = append(detailedInfo, daz.H("div", "Description:", daz.H("i", descriptionContent)))
+	}
+
+	{
+		if article.Image != "" {
+			detailedInfo = append(detailedInfo, daz.H("div", "Image:", daz.H("br"), daz.H("img", daz.Attr{"src": article.Image, "loading": "lazy", "style": "max-width: 256px;"})))
+		}
+	}
+
+	if len(article.Tags) > 0 {
+		detailedInfo = append(detailedInfo, daz.H("div", "Tags:", renderTagChips(article.Tags)))
+	}
+
+	detailedInfo = append(detailedInfo, daz.Attr{"class": "description"})
+	return daz.H("li", daz.H("details", titleLine, daz.H("div", detailedInfo...)))()
+}
+
 // makeTILHTML generates HTML from a []*entryGroup to make a list of articles
 func makeListHTML(groups []*entryGroup) string {
 
@@ -137,34 +171,19 @@ func makeListHTML(groups []*entryGroup) string {
 
 		header := daz.H(headerLevel, fmt.Sprintf("%s %d", group.Date.Month().String(), group.Date.Year()))
 
-		var entries []daz.HTML
+		var entriesHTML strings.Builder
 		for _, article := range group.Entries {
 
-			titleLine := daz.H("summary", renderAnchor(article.Title, article.URL, false), " - " + article.Date.Format(dateFormat))
-
-			detailedInfo := []interface{}{}
-
-			{
-				var descriptionContent string
-				if article.Description != "" {
-					descriptionContent = article.Description
-				} else {
-					descriptionContent = "<none>"
-				}
-				detailedInfo = append(detailedInfo, daz.H("div", "Description:", daz.H("i", descriptionContent)))
-			}
-
-			{
-				if article.Image != "" {
-					detailedInfo = append(detailedInfo, daz.H("div", "Image:", daz.H("br"), daz.H("img", daz.Attr{"src": article.Image, "loading": "lazy", "style": "max-width: 256px;"})))
-				}
+			entryHTML, ok := getCachedEntryHTML(article)
+			if !ok {
+				entryHTML = renderEntryHTML(article)
+				putCachedEntryHTML(article, entryHTML)
 			}
 
-			detailedInfo = append(detailedInfo, daz.Attr{"class": "description"})
-			entries = append(entries, daz.H("li", daz.H("details", titleLine, daz.H("div", detailedInfo...))))
+			entriesHTML.WriteString(entryHTML)
 		}
 
-		parts = append(parts, []daz.HTML{header, daz.H("ul", entries)})
+		parts = append(parts, []daz.HTML{header, daz.H("ul", daz.UnsafeContent(entriesHTML.String()))})
 	}
 
 	return daz.H("div", parts...)()
@@ -188,37 +207,95 @@ func GenerateSite() error {
 		return err
 	}
 
-	numArticles := len(entries)
 	groupedEntries := groupEntriesByMonth(entries)
 
-	const pageTitle = "akp's reading list"
-
-	head := daz.H(
-		"div",
-		daz.Attr{"class": "heading"},
-		daz.H("h1", pageTitle),
-		daz.H(
-			"p",
-			daz.Attr{"class": "information"},
-			daz.UnsafeContent(
-				fmt.Sprintf(
-					"A mostly complete list of articles I've read<br>There are currently %d entries in the list<br>Last modified %s<br>Repo: %s",
-					numArticles,
-					time.Now().Format(dateFormat),
-					renderUnsafeAnchor("<code>codemicro/readingList</code>", "https://github.com/codemicro/readingList", false)(),
-				),
-			),
-		),
-	)
-
-	listingHTML := makeListHTML(groupedEntries)
-
-	outputContent, err := renderHTMLPage(pageTitle, head(), listingHTML, "")
+	// Resolve tag slugs before rendering so that tag chips (rendered as
+	// part of the entries below) agree with the per-tag pages generateTagPages
+	// writes from the same tagCounts.
+	tagCounts := countTags(entries)
+
+	_ = os.Mkdir(".site", 0777)
+
+	for name, renderer := range renderers {
+		content, ext, err := renderer.Render(groupedEntries)
+		if err != nil {
+			return fmt.Errorf("rendering %q: %w", name, err)
+		}
+		if err := ioutil.WriteFile(fmt.Sprintf(".site/index.%s", ext), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	sortedEntries := make(entrySlice, len(entries))
+	copy(sortedEntries, entries)
+	sort.Sort(sortedEntries)
+
+	atomContent, err := buildAtomFeed(sortedEntries, pageTitle)
 	if err != nil {
 		return err
 	}
+	if err := ioutil.WriteFile(".site/feed.atom", atomContent, 0644); err != nil {
+		return err
+	}
 
-	_ = os.Mkdir(".site", 0777)
+	rssContent, err := buildRSSFeed(sortedEntries, pageTitle)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(".site/feed.xml", rssContent, 0644); err != nil {
+		return err
+	}
+
+	searchIndexContent, err := marshalSearchIndex(entries)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(".site/search-index.json", searchIndexContent, 0644); err != nil {
+		return err
+	}
+
+	return generateTagPages(entries, tagCounts)
+}
+
+// generateTagPages writes .site/tags/index.html plus one .site/tags/<slug>.html
+// per tag, each listing the entries carrying that tag. counts must come from
+// the same countTags call used to resolve the tag chip links rendered
+// elsewhere on the site, so that slugs agree everywhere.
+func generateTagPages(entries []*readingListEntry, counts tagCountSlice) error {
+	const tagsDir = ".site/tags"
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	_ = os.MkdirAll(tagsDir, 0777)
+
+	tagsIndexContent, err := renderHTMLPage(pageTitle+" - tags", daz.H("h1", "Tags")(), makeTagsIndexHTML(counts), "")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(tagsDir+"/index.html", tagsIndexContent, 0644); err != nil {
+		return err
+	}
+
+	for _, tc := range counts {
+		filtered := entriesWithTag(entries, tc.Tag)
+		groupedEntries := groupEntriesByMonth(filtered)
+		listingHTML := makeListHTML(groupedEntries)
+
+		outputContent, err := renderHTMLPage(
+			fmt.Sprintf("%s - %s", pageTitle, tc.Tag),
+			daz.H("h1", fmt.Sprintf("Tagged \"%s\"", tc.Tag))(),
+			listingHTML,
+			"",
+		)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(fmt.Sprintf("%s/%s.html", tagsDir, tc.Slug), outputContent, 0644); err != nil {
+			return err
+		}
+	}
 
-	return ioutil.WriteFile(".site/index.html", outputContent, 0644)
+	return nil
 }
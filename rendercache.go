@@ -0,0 +1,243 @@
+// +build mage
+
+package main
+
+import (
+	"bufio"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// templateVersion is bumped whenever makeListHTML/renderEntryHTML changes
+// the markup it produces, so that a template change invalidates every
+// cached entry at once rather than leaving stale fragments on disk.
+const templateVersion = 1
+
+const (
+	renderCacheDir      = ".cache/render"
+	renderCacheManifest = renderCacheDir + "/MANIFEST"
+	defaultMemoryLimit  = 256 << 20 // 256MiB, used if system RAM can't be determined
+)
+
+// lruCache is a simple size-bounded least-recently-used cache for rendered
+// entry HTML, sitting in front of the on-disk render cache.
+type lruCache struct {
+	mu    sync.Mutex
+	limit uint64
+	size  uint64
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	html string
+}
+
+func newLRUCache(limit uint64) *lruCache {
+	return &lruCache{
+		limit: limit,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).html, true
+}
+
+func (c *lruCache) put(key, html string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).html = html
+		return
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, html: html})
+	c.items[key] = el
+	c.size += uint64(len(html))
+
+	for c.size > c.limit && c.ll.Len() > 0 {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.ll.Remove(oldest)
+		ent := oldest.Value.(*lruEntry)
+		delete(c.items, ent.key)
+		c.size -= uint64(len(ent.html))
+	}
+}
+
+var (
+	renderCacheOnce sync.Once
+	memCache        *lruCache
+)
+
+// memoryLimitBytes determines the in-memory render cache's size cap,
+// overridable via READINGLIST_MEMORYLIMIT (e.g. "512MiB"), defaulting to
+// a quarter of total system RAM.
+func memoryLimitBytes() uint64 {
+	if raw := os.Getenv("READINGLIST_MEMORYLIMIT"); raw != "" {
+		if n, err := parseByteSize(raw); err == nil {
+			return n
+		}
+	}
+
+	if total := systemMemoryBytes(); total > 0 {
+		return total / 4
+	}
+
+	return defaultMemoryLimit
+}
+
+func parseByteSize(raw string) (uint64, error) {
+	raw = strings.TrimSpace(raw)
+	multipliers := []struct {
+		suffix string
+		factor uint64
+	}{
+		{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+		{"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	}
+
+	for _, m := range multipliers {
+		if strings.HasSuffix(raw, m.suffix) {
+			n, err := strconv.ParseUint(strings.TrimSuffix(raw, m.suffix), 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return n * m.factor, nil
+		}
+	}
+
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+// systemMemoryBytes reads total system RAM from /proc/meminfo. It returns 0
+// if that isn't available (e.g. non-Linux), leaving callers to fall back to
+// defaultMemoryLimit.
+func systemMemoryBytes() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "MemTotal:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+
+	return 0
+}
+
+// ensureRenderCache initialises the on-disk render cache directory,
+// wiping it if the template version has changed since it was last written.
+func ensureRenderCache() {
+	renderCacheOnce.Do(func() {
+		memCache = newLRUCache(memoryLimitBytes())
+
+		_ = os.MkdirAll(renderCacheDir, 0777)
+
+		wantManifest := strconv.Itoa(templateVersion)
+		gotManifest, _ := ioutil.ReadFile(renderCacheManifest)
+
+		if string(gotManifest) != wantManifest {
+			_ = os.RemoveAll(renderCacheDir)
+			_ = os.MkdirAll(renderCacheDir, 0777)
+			_ = ioutil.WriteFile(renderCacheManifest, []byte(wantManifest), 0644)
+		}
+	})
+}
+
+func renderCacheKey(entry *readingListEntry) string {
+	// The cached fragment includes tag chips whose hrefs depend on
+	// resolveTagSlug, which is only stable for the tag set countTags last
+	// saw. Folding the entry's currently-resolved slugs into the key means
+	// a collision-disambiguation shift elsewhere in the corpus (e.g. a
+	// renamed tag on an unrelated entry) invalidates exactly the entries
+	// whose chip links it actually changes, instead of serving a stale
+	// link from disk.
+	resolvedSlugs := make([]string, len(entry.Tags))
+	for i, tag := range entry.Tags {
+		resolvedSlugs[i] = resolveTagSlug(tag)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d|%s|%s|%s|%s|%s|%s|%s",
+		templateVersion,
+		entry.URL,
+		entry.Title,
+		entry.Description,
+		entry.Image,
+		entry.Date.Format(dateFormat),
+		strings.Join(entry.Tags, "|"),
+		strings.Join(resolvedSlugs, ","),
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func renderCachePath(key string) string {
+	return filepath.Join(renderCacheDir, key+".html")
+}
+
+// getCachedEntryHTML looks up a previously rendered entry fragment, first
+// in the in-memory LRU and then on disk.
+func getCachedEntryHTML(entry *readingListEntry) (string, bool) {
+	ensureRenderCache()
+
+	key := renderCacheKey(entry)
+	if html, ok := memCache.get(key); ok {
+		return html, true
+	}
+
+	data, err := ioutil.ReadFile(renderCachePath(key))
+	if err != nil {
+		return "", false
+	}
+
+	html := string(data)
+	memCache.put(key, html)
+	return html, true
+}
+
+func putCachedEntryHTML(entry *readingListEntry, html string) {
+	ensureRenderCache()
+
+	key := renderCacheKey(entry)
+	memCache.put(key, html)
+	_ = ioutil.WriteFile(renderCachePath(key), []byte(html), 0644)
+}
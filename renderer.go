@@ -0,0 +1,184 @@
+// +build mage
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stevelacy/daz"
+)
+
+// Renderer turns a grouped set of entries into a full output document.
+// Register implementations with RegisterRenderer so GenerateSite picks
+// them up without needing to know about them directly; this lets forks
+// add their own output formats without touching the core site generator.
+type Renderer interface {
+	// Render returns the rendered document and the file extension (without
+	// a leading dot) it should be written under in .site/.
+	Render(groups []*entryGroup) ([]byte, string, error)
+}
+
+var renderers = map[string]Renderer{}
+
+// RegisterRenderer adds a named Renderer to the set GenerateSite fans out
+// to. Call it from an init() func to register a renderer at package load.
+func RegisterRenderer(name string, r Renderer) {
+	renderers[name] = r
+}
+
+func init() {
+	RegisterRenderer("html", htmlRenderer{})
+	RegisterRenderer("json", jsonRenderer{})
+	RegisterRenderer("markdown", markdownRenderer{})
+	RegisterRenderer("gemtext", gemtextRenderer{})
+}
+
+func flattenGroups(groups []*entryGroup) []*readingListEntry {
+	var out []*readingListEntry
+	for _, group := range groups {
+		out = append(out, group.Entries...)
+	}
+	return out
+}
+
+// htmlRenderer renders the existing HTML page, including the search box.
+type htmlRenderer struct{}
+
+func (htmlRenderer) Render(groups []*entryGroup) ([]byte, string, error) {
+	numArticles := len(flattenGroups(groups))
+
+	head := daz.H(
+		"div",
+		daz.Attr{"class": "heading"},
+		daz.H("h1", pageTitle),
+		daz.H(
+			"p",
+			daz.Attr{"class": "information"},
+			daz.UnsafeContent(
+				fmt.Sprintf(
+					"A mostly complete list of articles I've read<br>There are currently %d entries in the list<br>Last modified %s<br>Repo: %s",
+					numArticles,
+					time.Now().Format(dateFormat),
+					renderUnsafeAnchor("<code>codemicro/readingList</code>", "https://github.com/codemicro/readingList", false)(),
+				),
+			),
+		),
+	)
+
+	searchBox := daz.H(
+		"div",
+		daz.Attr{"class": "search"},
+		daz.H("input", daz.Attr{"id": "search", "type": "search", "placeholder": "Search..."}),
+		daz.H("ul", daz.Attr{"id": "search-results"}),
+	)
+
+	listingHTML := makeListHTML(groups)
+
+	content, err := renderHTMLPage(pageTitle, head()+searchBox(), listingHTML, searchHeadScript())
+	return content, "html", err
+}
+
+// jsonRenderer renders a flat JSON array of entries for non-browser clients.
+type jsonRenderer struct{}
+
+// jsonEntry mirrors readingListEntry but with Tags as a real []string:
+// readingListEntry.Tags is a tagList, which implements encoding.TextMarshaler
+// (MarshalText joins with "|"), so marshaling readingListEntry directly
+// would serialise Tags as a single pipe-joined string instead of an array.
+type jsonEntry struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Image       string   `json:"image"`
+	Date        string   `json:"date"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+func (jsonRenderer) Render(groups []*entryGroup) ([]byte, string, error) {
+	entries := flattenGroups(groups)
+
+	out := make([]jsonEntry, len(entries))
+	for i, article := range entries {
+		out[i] = jsonEntry{
+			URL:         article.URL,
+			Title:       article.Title,
+			Description: article.Description,
+			Image:       article.Image,
+			Date:        article.Date.Format(dateFormat),
+			Tags:        article.Tags,
+		}
+	}
+
+	content, err := json.MarshalIndent(out, "", "  ")
+	return content, "json", err
+}
+
+// stripControlChars replaces newlines and other control characters in
+// untrusted text with spaces (e.g. titles/descriptions pulled from a third-party
+// page by EnrichEntries) so it can't splice extra lines into a plain-text
+// output format.
+func stripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || (r < 0x20) || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+var markdownEscaper = strings.NewReplacer("[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)")
+
+// escapeMarkdownText sanitises untrusted text for use inside Markdown link
+// syntax, so it can't close the link early or open a new one.
+func escapeMarkdownText(s string) string {
+	return markdownEscaper.Replace(stripControlChars(s))
+}
+
+// markdownRenderer renders the list as month-grouped Markdown suitable for
+// GitHub's own file preview.
+type markdownRenderer struct{}
+
+func (markdownRenderer) Render(groups []*entryGroup) ([]byte, string, error) {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", pageTitle)
+
+	for _, group := range groups {
+		fmt.Fprintf(&sb, "## %s %d\n\n", group.Date.Month().String(), group.Date.Year())
+		for _, article := range group.Entries {
+			fmt.Fprintf(&sb, "- [%s](%s) - %s\n", escapeMarkdownText(article.Title), article.URL, article.Date.Format(dateFormat))
+			if article.Description != "" {
+				fmt.Fprintf(&sb, "  %s\n", escapeMarkdownText(article.Description))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return []byte(sb.String()), "md", nil
+}
+
+// gemtextRenderer renders the list as Gemini text/gemini for gemini://
+// mirrors.
+type gemtextRenderer struct{}
+
+func (gemtextRenderer) Render(groups []*entryGroup) ([]byte, string, error) {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", pageTitle)
+
+	for _, group := range groups {
+		fmt.Fprintf(&sb, "## %s %d\n\n", group.Date.Month().String(), group.Date.Year())
+		for _, article := range group.Entries {
+			fmt.Fprintf(&sb, "=> %s %s (%s)\n", article.URL, stripControlChars(article.Title), article.Date.Format(dateFormat))
+			if article.Description != "" {
+				fmt.Fprintf(&sb, "%s\n", stripControlChars(article.Description))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return []byte(sb.String()), "gmi", nil
+}
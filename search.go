@@ -0,0 +1,147 @@
+// +build mage
+
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// searchIndexEntry carries only what searchHeadScript's client code reads
+// (url, title, date). Description drives tokenization into Index below but
+// isn't shipped itself, and tags aren't used at all, keeping
+// search-index.json small.
+type searchIndexEntry struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Date  string `json:"date"`
+}
+
+type searchIndex struct {
+	Entries []searchIndexEntry `json:"entries"`
+	// Index maps a lowercased, punctuation-stripped token to the list of
+	// entry indices (into Entries) whose title or description contain it.
+	Index map[string][]int `json:"index"`
+}
+
+var searchTokenSplitter = regexp.MustCompile(`[^a-z0-9]+`)
+
+// tokenize lowercases s and splits it on runs of non-alphanumeric characters.
+func tokenize(s string) []string {
+	s = strings.ToLower(s)
+	fields := searchTokenSplitter.Split(s, -1)
+
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// buildSearchIndex builds an inverted index over entry titles and
+// descriptions so the client can look up a token in O(1) rather than
+// shipping every entry's raw text to the browser.
+func buildSearchIndex(entries []*readingListEntry) *searchIndex {
+	idx := &searchIndex{Index: make(map[string][]int)}
+
+	for i, entry := range entries {
+		idx.Entries = append(idx.Entries, searchIndexEntry{
+			URL:   entry.URL,
+			Title: entry.Title,
+			Date:  entry.Date.Format(dateFormat),
+		})
+
+		seen := make(map[string]bool)
+		for _, tok := range tokenize(entry.Title + " " + entry.Description) {
+			if seen[tok] {
+				continue
+			}
+			seen[tok] = true
+			idx.Index[tok] = append(idx.Index[tok], i)
+		}
+	}
+
+	for tok := range idx.Index {
+		sort.Ints(idx.Index[tok])
+	}
+
+	return idx
+}
+
+func marshalSearchIndex(entries []*readingListEntry) ([]byte, error) {
+	return json.Marshal(buildSearchIndex(entries))
+}
+
+// searchHeadScript returns a <script> block wiring up a search box that
+// queries the prebuilt search-index.json. Ranking is the sum of
+// log(N/df) across matched query tokens, tied-broken by recency.
+func searchHeadScript() string {
+	return `<script>
+(function() {
+	var N = 0, index = null, entries = null;
+
+	function tokenize(s) {
+		return s.toLowerCase().split(/[^a-z0-9]+/).filter(Boolean);
+	}
+
+	function search(query) {
+		if (!index) return [];
+		var scores = {};
+		tokenize(query).forEach(function(tok) {
+			var postings = index[tok];
+			if (!postings) return;
+			var weight = Math.log(N / postings.length);
+			postings.forEach(function(i) {
+				scores[i] = (scores[i] || 0) + weight;
+			});
+		});
+		return Object.keys(scores)
+			.map(function(i) { return { entry: entries[i], score: scores[i] }; })
+			.sort(function(a, b) {
+				if (b.score !== a.score) return b.score - a.score;
+				return b.entry.date.localeCompare(a.entry.date);
+			});
+	}
+
+	function render(results) {
+		var out = document.getElementById("search-results");
+		if (!out) return;
+		out.innerHTML = "";
+		results.slice(0, 20).forEach(function(r) {
+			var a = document.createElement("a");
+			a.href = r.entry.url;
+			a.rel = "noopener";
+			a.textContent = r.entry.title;
+			var li = document.createElement("li");
+			li.appendChild(a);
+			out.appendChild(li);
+		});
+	}
+
+	window.addEventListener("DOMContentLoaded", function() {
+		var box = document.getElementById("search");
+		if (!box) return;
+
+		fetch("/search-index.json")
+			.then(function(resp) { return resp.json(); })
+			.then(function(data) {
+				entries = data.entries;
+				index = data.index;
+				N = entries.length;
+			});
+
+		box.addEventListener("input", function() {
+			if (!box.value) {
+				render([]);
+				return;
+			}
+			render(search(box.value));
+		});
+	});
+})();
+</script>`
+}
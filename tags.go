@@ -0,0 +1,167 @@
+// +build mage
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stevelacy/daz"
+)
+
+// tagList is a []string that (de)serialises to a single CSV column as a
+// comma- or pipe-separated list, since csvutil has no native slice support.
+type tagList []string
+
+func (t tagList) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(t, "|")), nil
+}
+
+func (t *tagList) UnmarshalText(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "" {
+		*t = nil
+		return nil
+	}
+
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+	out := make(tagList, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		out = append(out, part)
+	}
+
+	*t = out
+	return nil
+}
+
+var tagSlugDisallowed = regexp.MustCompile(`[^a-z0-9]+`)
+
+// tagSlug turns a tag into a filesystem- and URL-safe slug.
+func tagSlug(tag string) string {
+	slug := tagSlugDisallowed.ReplaceAllString(strings.ToLower(tag), "-")
+	return strings.Trim(slug, "-")
+}
+
+type tagCount struct {
+	Tag   string
+	Slug  string
+	Count int
+}
+
+type tagCountSlice []tagCount
+
+func (t tagCountSlice) Len() int      { return len(t) }
+func (t tagCountSlice) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t tagCountSlice) Less(i, j int) bool {
+	if t[i].Count != t[j].Count {
+		return t[i].Count > t[j].Count
+	}
+	return t[i].Tag < t[j].Tag
+}
+
+// tagSlugs holds the slug chosen for each tag by the most recent countTags
+// call, so that tag chips rendered mid-page agree with the per-tag page
+// filenames countTags lays out.
+var tagSlugs = map[string]string{}
+
+// countTags tallies how many entries carry each tag and assigns each tag a
+// unique slug, disambiguating collisions (e.g. "C++" and "C#" both slugify
+// to "c") by appending "-2", "-3", etc. in the returned order (highest
+// count first, then by tag name), and falling back to
+// "tag" for tags that slugify to an empty string (e.g. all-punctuation).
+// Without this, colliding tags would overwrite each other's .site/tags/
+// page, silently misattributing content once the list grows large.
+func countTags(entries []*readingListEntry) tagCountSlice {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+
+	out := make(tagCountSlice, 0, len(counts))
+	for tag, count := range counts {
+		out = append(out, tagCount{Tag: tag, Count: count})
+	}
+	sort.Sort(out)
+
+	used := make(map[string]bool, len(out))
+	resolved := make(map[string]string, len(out))
+	for i := range out {
+		base := tagSlug(out[i].Tag)
+		if base == "" {
+			base = "tag"
+		}
+
+		slug := base
+		for n := 2; used[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[slug] = true
+
+		out[i].Slug = slug
+		resolved[out[i].Tag] = slug
+	}
+	tagSlugs = resolved
+
+	return out
+}
+
+// resolveTagSlug returns the disambiguated slug most recently assigned to
+// tag by countTags, falling back to the raw slug if countTags hasn't run
+// yet for the current tag set.
+func resolveTagSlug(tag string) string {
+	if slug, ok := tagSlugs[tag]; ok {
+		return slug
+	}
+	return tagSlug(tag)
+}
+
+// entriesWithTag filters entries down to those carrying the given tag.
+func entriesWithTag(entries []*readingListEntry, tag string) []*readingListEntry {
+	var out []*readingListEntry
+	for _, entry := range entries {
+		for _, t := range entry.Tags {
+			if t == tag {
+				out = append(out, entry)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// renderTagChips renders a row of tag chips, each linking to its per-tag page.
+func renderTagChips(tags []string) daz.HTML {
+	if len(tags) == 0 {
+		return daz.H("span")
+	}
+
+	var chips []daz.HTML
+	for _, tag := range tags {
+		chips = append(chips, renderAnchor(tag, fmt.Sprintf("/tags/%s.html", resolveTagSlug(tag)), false))
+	}
+
+	return daz.H("div", daz.Attr{"class": "tags"}, chips)
+}
+
+// makeTagsIndexHTML renders the tags/index.html listing every tag and its count.
+func makeTagsIndexHTML(counts tagCountSlice) string {
+	var items []daz.HTML
+	for _, tc := range counts {
+		items = append(items, daz.H(
+			"li",
+			renderAnchor(fmt.Sprintf("%s (%d)", tc.Tag, tc.Count), fmt.Sprintf("/tags/%s.html", tc.Slug), false),
+		))
+	}
+
+	return daz.H("div", daz.H("ul", items))()
+}